@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// ExportFormat selects the file format ExportPlaylist writes.
+type ExportFormat string
+
+const (
+	ExportFormatM3U8 ExportFormat = "m3u8"
+	ExportFormatJSPF ExportFormat = "jspf"
+)
+
+// EnumeratePlaylistTracks retrieves every track in a playlist, paging past
+// the first 100 that GetPlaylist alone would return.
+func EnumeratePlaylistTracks(
+	ctx context.Context,
+	client *spotify.Client,
+	playlistId spotify.ID,
+) ([]spotify.PlaylistTrack, error) {
+	page, err := client.GetPlaylistTracks(ctx, playlistId)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := append([]spotify.PlaylistTrack{}, page.Tracks...)
+
+	for {
+		if err := client.NextPage(ctx, page); errors.Is(err, spotify.ErrNoMorePages) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		tracks = append(tracks, page.Tracks...)
+	}
+
+	return tracks, nil
+}
+
+// ExportPlaylist writes every track of playlistId to w in the given format.
+func ExportPlaylist(
+	ctx context.Context,
+	client *spotify.Client,
+	playlistId spotify.ID,
+	format ExportFormat,
+	w io.Writer,
+) error {
+	playlist, err := GetPlaylist(ctx, client, playlistId)
+	if err != nil {
+		return err
+	}
+
+	tracks, err := EnumeratePlaylistTracks(ctx, client, playlistId)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case ExportFormatM3U8:
+		return writeM3U8(w, tracks)
+	case ExportFormatJSPF:
+		return writeJSPF(w, playlist.Name, tracks)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// writeM3U8 writes tracks as an M3U8 playlist.
+func writeM3U8(w io.Writer, tracks []spotify.PlaylistTrack) error {
+	if _, err := fmt.Fprintln(w, "#EXTM3U"); err != nil {
+		return err
+	}
+
+	for _, item := range tracks {
+		track := item.Track
+
+		if _, err := fmt.Fprintf(
+			w,
+			"#EXTINF:%d,%s - %s\n%s\n",
+			track.Duration/1000,
+			trackArtists(track),
+			track.Name,
+			track.URI,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// jspfDoc and jspfTrack mirror the JSON Playlist Format (JSPF) spec.
+type jspfDoc struct {
+	Playlist struct {
+		Title string      `json:"title"`
+		Track []jspfTrack `json:"track"`
+	} `json:"playlist"`
+}
+
+type jspfTrack struct {
+	Identifier string `json:"identifier"`
+	Title      string `json:"title"`
+	Creator    string `json:"creator"`
+	Album      string `json:"album"`
+	Duration   int    `json:"duration"`
+}
+
+// writeJSPF writes tracks as a JSPF playlist titled title.
+func writeJSPF(w io.Writer, title string, tracks []spotify.PlaylistTrack) error {
+	var doc jspfDoc
+	doc.Playlist.Title = title
+	doc.Playlist.Track = make([]jspfTrack, len(tracks))
+
+	for i, item := range tracks {
+		track := item.Track
+
+		doc.Playlist.Track[i] = jspfTrack{
+			Identifier: string(track.URI),
+			Title:      track.Name,
+			Creator:    trackArtists(track),
+			Album:      track.Album.Name,
+			Duration:   int(track.Duration),
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// trackArtists joins a track's artist names for display, e.g. in an
+// #EXTINF line or a JSPF "creator" field.
+func trackArtists(track spotify.FullTrack) string {
+	artists := make([]string, len(track.Artists))
+	for i, artist := range track.Artists {
+		artists[i] = artist.Name
+	}
+
+	return strings.Join(artists, ", ")
+}
+
+// exportPartitionedPlaylists writes every one of the user's playlists whose
+// name matches strategy.Pattern() to dir, one file per playlist, as a
+// portable backup independent of Spotify. Playlists are selected by name
+// only, so for strategies whose pattern matches any name (artist, genre)
+// every playlist of the user is exported.
+func exportPartitionedPlaylists(
+	ctx context.Context,
+	client *spotify.Client,
+	strategy PartitionStrategy,
+	dir string,
+	format ExportFormat,
+) error {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	playlists, err := EnumerateCurrentUsersPlaylists(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	pattern := strategy.Pattern()
+
+	for _, playlist := range playlists {
+		if !pattern.MatchString(playlist.Name) {
+			continue
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("%s.%s", playlist.Name, format))
+
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+
+		err = ExportPlaylist(ctx, client, playlist.ID, format, file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+
+		log.Printf("exported %s to %s", playlist.Name, path)
+	}
+
+	return nil
+}
@@ -1,21 +1,85 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"flag"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/zmb3/spotify/v2"
 	"golang.org/x/oauth2"
 )
 
+// ErrUnauthorized indicates the Spotify API rejected the request's access
+// token. Callers should refresh the token and retry rather than treating
+// this as a transient failure.
+var ErrUnauthorized = errors.New("spotify: access token rejected (401)")
+
+// maxRetries is the number of retry attempts retryTransport makes for rate
+// limited or server-error responses before giving up.
+const maxRetries = 5
+
+// retryTransport wraps an http.RoundTripper, retrying requests that come
+// back 429 (honoring the Retry-After header) or 5xx with exponential
+// backoff. It surfaces a 401 as ErrUnauthorized instead of retrying, since
+// retrying with the same token would only fail again.
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := time.Second
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized {
+			resp.Body.Close()
+			return nil, ErrUnauthorized
+		}
+
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !retryable || attempt >= maxRetries {
+			return resp, nil
+		}
+
+		wait := backoff
+		if resp.StatusCode == http.StatusTooManyRequests {
+			if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+				if seconds, err := strconv.Atoi(retryAfter); err == nil {
+					wait = time.Duration(seconds) * time.Second
+				}
+			}
+		}
+		resp.Body.Close()
+
+		log.Printf(
+			"spotify request to %s failed with status %d, retrying in %s",
+			req.URL, resp.StatusCode, wait,
+		)
+		time.Sleep(wait)
+		backoff *= 2
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
 // ReadTokenFromPath reads a token from a file at the given path.
 func ReadTokenFromPath(path string) (*oauth2.Token, error) {
 	file, err := os.Open(path)
@@ -34,244 +98,108 @@ func ReadTokenFromPath(path string) (*oauth2.Token, error) {
 
 // GetPlaylist retrieves a playlist by its ID.
 func GetPlaylist(
-	client *http.Client,
-	playlistId string,
-) (map[string]interface{}, error) {
-	resp, err := client.Get(
-		fmt.Sprintf("https://api.spotify.com/v1/playlists/%s", playlistId),
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	var playlist map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&playlist); err != nil {
-		return nil, err
-	}
-
-	return playlist, nil
+	ctx context.Context,
+	client *spotify.Client,
+	playlistId spotify.ID,
+) (*spotify.FullPlaylist, error) {
+	return client.GetPlaylist(ctx, playlistId)
 }
 
 // GetCurrentUsersProfile retrieves the profile of the current user.
 func GetCurrentUsersProfile(
-	client *http.Client,
-) (map[string]interface{}, error) {
-	resp, err := client.Get("https://api.spotify.com/v1/me")
-	if err != nil {
-		return nil, err
-	}
-
-	var profile map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
-		return nil, err
-	}
-
-	return profile, nil
+	ctx context.Context,
+	client *spotify.Client,
+) (*spotify.PrivateUser, error) {
+	return client.CurrentUser(ctx)
 }
 
 // EnumerateCurrentUsersPlaylists retrieves all playlists of the current user.
 func EnumerateCurrentUsersPlaylists(
-	client *http.Client,
-) ([]interface{}, error) {
-	resp, err := client.Get("https://api.spotify.com/v1/me/playlists")
+	ctx context.Context,
+	client *spotify.Client,
+) ([]spotify.SimplePlaylist, error) {
+	page, err := client.CurrentUsersPlaylists(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	var playlists map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&playlists); err != nil {
-		return nil, err
-	}
-
-	items, ok := playlists["items"].([]interface{})
-	if !ok {
-		return nil, fmt.Errorf(
-			"unexpected type for items: %T",
-			playlists["items"],
-		)
-	}
 
-	next, ok := playlists["next"]
-	if !ok {
-		return nil, fmt.Errorf(
-			"unexpected type for next: %T",
-			playlists["next"],
-		)
-	}
-
-	for next != nil {
-		resp, err := client.Get(next.(string))
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
+	playlists := append([]spotify.SimplePlaylist{}, page.Playlists...)
 
-		if err := json.NewDecoder(resp.Body).Decode(&playlists); err != nil {
+	for {
+		if err := client.NextPage(ctx, page); errors.Is(err, spotify.ErrNoMorePages) {
+			break
+		} else if err != nil {
 			return nil, err
 		}
 
-		nextItems, ok := playlists["items"].([]interface{})
-		if !ok {
-			return nil, fmt.Errorf(
-				"unexpected type for items: %T",
-				playlists["items"],
-			)
-		}
-
-		items = append(items, nextItems...)
-
-		next, ok = playlists["next"]
-		if !ok {
-			return nil, fmt.Errorf(
-				"unexpected type for next: %T",
-				playlists["next"],
-			)
-		}
+		playlists = append(playlists, page.Playlists...)
 	}
 
-	return items, nil
+	return playlists, nil
 }
 
 // EnumerateUsersSavedTracks retrieves all saved tracks of the current user.
-func EnumerateUsersSavedTracks(client *http.Client) ([]interface{}, error) {
-	resp, err := client.Get("https://api.spotify.com/v1/me/tracks")
+func EnumerateUsersSavedTracks(
+	ctx context.Context,
+	client *spotify.Client,
+) ([]spotify.SavedTrack, error) {
+	page, err := client.CurrentUsersTracks(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	var tracks map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&tracks); err != nil {
-		return nil, err
-	}
-
-	items, ok := tracks["items"].([]interface{})
-	if !ok {
-		return nil, fmt.Errorf("unexpected type for items: %T", tracks["items"])
-	}
-
-	next, ok := tracks["next"]
-	if !ok {
-		return nil, fmt.Errorf("unexpected type for next: %T", tracks["next"])
-	}
 
-	for next != nil {
-		resp, err := client.Get(next.(string))
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
+	tracks := append([]spotify.SavedTrack{}, page.Tracks...)
 
-		if err := json.NewDecoder(resp.Body).Decode(&tracks); err != nil {
+	for {
+		if err := client.NextPage(ctx, page); errors.Is(err, spotify.ErrNoMorePages) {
+			break
+		} else if err != nil {
 			return nil, err
 		}
 
-		nextItems, ok := tracks["items"].([]interface{})
-		if !ok {
-			return nil, fmt.Errorf(
-				"unexpected type for items: %T",
-				tracks["items"],
-			)
-		}
-
-		items = append(items, nextItems...)
-
-		next, ok = tracks["next"]
-		if !ok {
-			return nil, fmt.Errorf(
-				"unexpected type for next: %T",
-				tracks["next"],
-			)
-		}
+		tracks = append(tracks, page.Tracks...)
 	}
 
-	return items, nil
+	return tracks, nil
 }
 
-// AddItemsToPlaylist adds items to a playlist.
+// maxTracksPerRequest is Spotify's hard cap on the number of tracks accepted
+// by a single call to the add-items-to-playlist endpoint.
+const maxTracksPerRequest = 100
+
+// AddItemsToPlaylist adds tracks to a playlist, chunking trackIds into
+// batches of maxTracksPerRequest since Spotify rejects larger payloads.
 func AddItemsToPlaylist(
-	client *http.Client,
-	playlistId string,
-	uris []string,
+	ctx context.Context,
+	client *spotify.Client,
+	playlistId spotify.ID,
+	trackIds []spotify.ID,
 ) error {
-	body := map[string]interface{}{
-		"uris": uris,
-	}
-
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequest(
-		http.MethodPost,
-		fmt.Sprintf(
-			"https://api.spotify.com/v1/playlists/%s/tracks",
-			playlistId,
-		),
-		bytes.NewBuffer(jsonBody),
-	)
-	if err != nil {
-		return err
-	}
-
-	req.Header.Add("Content-Type", "application/json")
+	for start := 0; start < len(trackIds); start += maxTracksPerRequest {
+		end := start + maxTracksPerRequest
+		if end > len(trackIds) {
+			end = len(trackIds)
+		}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
+		if _, err := client.AddTracksToPlaylist(ctx, playlistId, trackIds[start:end]...); err != nil {
+			return err
+		}
 	}
-	defer resp.Body.Close()
 
 	return nil
 }
 
 // CreatePlaylist creates a playlist.
 func CreatePlaylist(
-	client *http.Client,
+	ctx context.Context,
+	client *spotify.Client,
 	userId string,
 	name string,
 	public bool,
 	collaborative bool,
 	description string,
-) (map[string]interface{}, error) {
-	body := map[string]interface{}{
-		"name":          name,
-		"public":        public,
-		"collaborative": collaborative,
-		"description":   description,
-	}
-
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		return nil, err
-	}
-
-	req, err := http.NewRequest(
-		http.MethodPost,
-		fmt.Sprintf("https://api.spotify.com/v1/users/%s/playlists", userId),
-		bytes.NewBuffer(jsonBody),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Add("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	var playlist map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&playlist); err != nil {
-		return nil, err
-	}
-
-	return playlist, nil
+) (*spotify.FullPlaylist, error) {
+	return client.CreatePlaylistForUser(ctx, userId, name, description, public, collaborative)
 }
 
 // WriteTokenToPath writes a token to a file at the given path.
@@ -294,71 +222,135 @@ func WriteTokenToPath(path string, token *oauth2.Token) error {
 }
 
 // DeletePlaylist deletes a playlist by its ID.
-func DeletePlaylist(client *http.Client, playlistId string) error {
-	req, err := http.NewRequest(
-		http.MethodDelete,
-		fmt.Sprintf(
-			"https://api.spotify.com/v1/playlists/%s/followers",
-			playlistId,
-		),
-		nil,
-	)
-	if err != nil {
-		return err
-	}
+func DeletePlaylist(ctx context.Context, client *spotify.Client, playlistId spotify.ID) error {
+	return client.UnfollowPlaylist(ctx, playlistId)
+}
 
-	resp, err := client.Do(req)
+// DeletePlaylistsByNameFormat deletes playlists whose name matches the
+// partition strategy's bucket naming pattern.
+func DeletePlaylistsByNameFormat(
+	ctx context.Context,
+	client *spotify.Client,
+	strategy PartitionStrategy,
+) error {
+	playlists, err := EnumerateCurrentUsersPlaylists(ctx, client)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf(
-			"failed to delete playlist, status code: %d",
-			resp.StatusCode,
-		)
+	pattern := strategy.Pattern()
+
+	for _, playlist := range playlists {
+		if pattern.MatchString(playlist.Name) {
+			log.Printf("deleting playlist: %s", playlist.Name)
+			if err := DeletePlaylist(ctx, client, playlist.ID); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
-// DeletePlaylistsByNameFormat deletes playlists by a regex pattern.
-func DeletePlaylistsByNameFormat(
-	client *http.Client,
-	regexPattern string,
-) error {
-	playlists, err := EnumerateCurrentUsersPlaylists(client)
+// syncPlaylists fetches the user's saved tracks and files each one into the
+// playlist strategy buckets it into, creating playlists that don't exist
+// yet.
+func syncPlaylists(ctx context.Context, client *spotify.Client, strategy PartitionStrategy) error {
+	profile, err := GetCurrentUsersProfile(ctx, client)
 	if err != nil {
 		return err
 	}
 
-	regex, err := regexp.Compile(regexPattern)
+	playlists, err := EnumerateCurrentUsersPlaylists(ctx, client)
 	if err != nil {
 		return err
 	}
 
-	for _, playlist := range playlists {
-		plMap, ok := playlist.(map[string]interface{})
-		if !ok {
-			log.Fatalf("unexpected type for playlist: %T", playlist)
+	tracks, err := EnumerateUsersSavedTracks(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	if primer, ok := strategy.(Primer); ok {
+		if err := primer.Prime(ctx, client, tracks); err != nil {
+			return err
 		}
+	}
 
-		playlistName, ok := plMap["name"].(string)
-		if !ok {
-			log.Printf("unexpected type for name: %T", plMap["name"])
+	reverseTracks := make([]spotify.SavedTrack, len(tracks))
+	for i, j := 0, len(tracks)-1; i < j; i, j = i+1, j-1 {
+		reverseTracks[i], reverseTracks[j] = tracks[j], tracks[i]
+	}
+
+	// Map to keep track of created/target playlists for each bucket
+	playlistMap := make(map[string]spotify.ID)
+	tracksByBucket := make(map[string][]spotify.ID)
+
+	for _, track := range reverseTracks {
+		targetPlaylistName := strategy.Bucket(track)
+		tracksByBucket[targetPlaylistName] = append(
+			tracksByBucket[targetPlaylistName],
+			track.ID,
+		)
+
+		if _, exists := playlistMap[targetPlaylistName]; !exists {
+			for _, playlist := range playlists {
+				if playlist.Name == targetPlaylistName {
+					log.Printf("found %s", targetPlaylistName)
+					playlistMap[targetPlaylistName] = playlist.ID
+					break
+				}
+			}
+
+			if _, exists := playlistMap[targetPlaylistName]; !exists {
+				log.Printf("creating %s", targetPlaylistName)
+				playlist, err := CreatePlaylist(
+					ctx,
+					client,
+					string(profile.ID),
+					targetPlaylistName,
+					true,
+					false,
+					"",
+				)
+				if err != nil {
+					return err
+				}
+
+				playlistMap[targetPlaylistName] = playlist.ID
+			}
+		}
+	}
+
+	for bucket, trackIds := range tracksByBucket {
+		if len(trackIds) == 0 {
+			continue
+		}
+
+		targetPlaylistId := playlistMap[bucket]
+
+		playlist, err := GetPlaylist(ctx, client, targetPlaylistId)
+		if err != nil {
+			return err
 		}
 
-		if regex.MatchString(playlistName) {
-			log.Printf("deleting playlist: %s", playlistName)
-			playlistId, ok := plMap["id"].(string)
-			if !ok {
-				log.Printf("unexpected type for id: %T", plMap["id"])
+		existingTracks := make(map[spotify.ID]bool)
+		for _, item := range playlist.Tracks.Tracks {
+			existingTracks[item.Track.ID] = true
+		}
+
+		newTrackIds := []spotify.ID{}
+		for _, trackId := range trackIds {
+			if !existingTracks[trackId] {
+				newTrackIds = append(newTrackIds, trackId)
 			}
+		}
 
-			if err := DeletePlaylist(client, playlistId); err != nil {
+		if len(newTrackIds) > 0 {
+			if err := AddItemsToPlaylist(ctx, client, targetPlaylistId, newTrackIds); err != nil {
 				return err
 			}
+			log.Printf("added %d tracks to %s", len(newTrackIds), bucket)
 		}
 	}
 
@@ -371,6 +363,33 @@ func main() {
 		log.Fatalln(err)
 	}
 
+	schedule := flag.String(
+		"schedule",
+		os.Getenv("SPOTIFY_SYNC_SCHEDULE"),
+		"cron expression to re-run the sync on a recurring basis (e.g. \"0 */6 * * *\"); if unset, syncs once and exits",
+	)
+	exportDir := flag.String(
+		"export",
+		"",
+		"after syncing, write each partitioned playlist to <dir> as a portable backup",
+	)
+	exportFormat := flag.String(
+		"export-format",
+		string(ExportFormatM3U8),
+		"export format: m3u8 or jspf",
+	)
+	partition := flag.String(
+		"partition",
+		"month",
+		"how to bucket saved tracks into playlists: month, week, quarter, year, decade, artist, or genre",
+	)
+	flag.Parse()
+
+	strategy, err := partitionStrategyByName(*partition)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
 	clientId := os.Getenv("SPOTIFY_CLIENT_ID")
 	clientSecret := os.Getenv("SPOTIFY_CLIENT_SECRET")
 	redirectUri := os.Getenv("SPOTIFY_REDIRECT_URI")
@@ -407,16 +426,7 @@ func main() {
 
 	token, err := ReadTokenFromPath(path)
 	if err != nil {
-		url := config.AuthCodeURL("state")
-		log.Println("Visit the URL for the auth dialog:", url)
-		log.Println("Enter the code:")
-
-		var code string
-		if _, err := fmt.Scan(&code); err != nil {
-			log.Fatalln(err)
-		}
-
-		token, err = config.Exchange(ctx, code)
+		token, err = authorize(ctx, config)
 		if err != nil {
 			log.Fatalln(err)
 		}
@@ -439,161 +449,69 @@ func main() {
 		}
 	}
 
-	client := config.Client(ctx, newToken)
-
-	// if err := DeletePlaylistsByNameFormat(client, `^[A-Za-z]+\s'\d{2}$`); err != nil {
-	// 	log.Fatalln(err)
-	// }
-
-	profile, err := GetCurrentUsersProfile(client)
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	id, ok := profile["id"].(string)
-	if !ok {
-		log.Fatalf("unexpected type for id: %T", profile["id"])
-	}
-
-	playlists, err := EnumerateCurrentUsersPlaylists(client)
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	tracks, err := EnumerateUsersSavedTracks(client)
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	reverseTracks := make([]interface{}, len(tracks))
-	for i, j := 0, len(tracks)-1; i < j; i, j = i+1, j-1 {
-		reverseTracks[i], reverseTracks[j] = tracks[j], tracks[i]
+	newClient := func(token *oauth2.Token) *spotify.Client {
+		httpClient := config.Client(ctx, token)
+		httpClient.Transport = &retryTransport{base: httpClient.Transport}
+		return spotify.New(httpClient)
 	}
 
-	// Map to keep track of created/target playlists for each month
-	playlistMap := make(map[string]string)
-	tracksByMonth := make(map[string][]string)
-
-	for _, track := range reverseTracks {
-		trackMap, ok := track.(map[string]interface{})
-		if !ok {
-			log.Fatalf("unexpected type for track: %T", track)
-		}
-
-		addedAt, ok := trackMap["added_at"].(string)
-		if !ok {
-			log.Fatalf("unexpected type for added_at: %T", trackMap["added_at"])
-		}
+	client := newClient(newToken)
 
-		trackMap = trackMap["track"].(map[string]interface{})
-		if !ok {
-			log.Fatalf("unexpected type for track: %T", trackMap["track"])
-		}
+	// if err := DeletePlaylistsByNameFormat(ctx, client, strategy); err != nil {
+	// 	log.Fatalln(err)
+	// }
 
-		t, err := time.Parse(time.RFC3339, addedAt)
+	// reauthorize forces a token refresh, ignoring the cached expiry, and
+	// rebuilds client against the refreshed token. It's called when a sync
+	// comes back with ErrUnauthorized, which a proactive refresh based on
+	// the token's stated expiry wouldn't have caught (e.g. a token revoked
+	// early on Spotify's side).
+	reauthorize := func() error {
+		newToken.Expiry = time.Now()
+		refreshed, err := config.TokenSource(ctx, newToken).Token()
 		if err != nil {
-			log.Fatalln(err)
+			return err
 		}
 
-		targetPlaylistName := t.Format("January '06")
-		uri := trackMap["uri"].(string)
-		tracksByMonth[targetPlaylistName] = append(
-			tracksByMonth[targetPlaylistName],
-			uri,
-		)
-
-		if _, exists := playlistMap[targetPlaylistName]; !exists {
-			for _, playlist := range playlists {
-				plMap, ok := playlist.(map[string]interface{})
-				if !ok {
-					log.Fatalf("unexpected type for playlist: %T", playlist)
-				}
-
-				playlistName, ok := plMap["name"].(string)
-				if !ok {
-					log.Printf(
-						"unexpected type for playlistName: %T",
-						plMap["name"],
-					)
-				}
-
-				if playlistName == targetPlaylistName {
-					log.Printf("found %s", targetPlaylistName)
-					playlistMap[targetPlaylistName] = plMap["id"].(string)
-					break
-				}
-			}
-
-			if _, exists := playlistMap[targetPlaylistName]; !exists {
-				log.Printf("creating %s", targetPlaylistName)
-				playlist, err := CreatePlaylist(
-					client,
-					id,
-					targetPlaylistName,
-					true,
-					false,
-					"",
-				)
-				if err != nil {
-					log.Fatalln(err)
-				}
-
-				playlistMap[targetPlaylistName] = playlist["id"].(string)
-			}
+		if err := WriteTokenToPath(path, refreshed); err != nil {
+			return err
 		}
-	}
-
-	for month, uris := range tracksByMonth {
-		if len(uris) > 0 {
-			targetPlaylistId := playlistMap[month]
 
-			playlist, err := GetPlaylist(client, targetPlaylistId)
-			if err != nil {
-				log.Fatalln(err)
-			}
+		newToken = refreshed
+		client = newClient(refreshed)
+		return nil
+	}
 
-			existingTracks := make(map[string]bool)
-			tracks, ok := playlist["tracks"].(map[string]interface{})
-			if !ok {
-				log.Fatalf("unexpected type for tracks: %T", playlist["tracks"])
+	sync := func() error {
+		if err := syncPlaylists(ctx, client, strategy); err != nil {
+			if !errors.Is(err, ErrUnauthorized) {
+				return err
 			}
 
-			items, ok := tracks["items"].([]interface{})
-			if !ok {
-				log.Fatalf("unexpected type for items: %T", tracks["items"])
+			log.Println("access token rejected, refreshing and retrying")
+			if err := reauthorize(); err != nil {
+				return err
 			}
 
-			for _, item := range items {
-				itemMap, ok := item.(map[string]interface{})
-				if !ok {
-					log.Fatalf("unexpected type for item: %T", item)
-				}
-
-				trackMap, ok := itemMap["track"].(map[string]interface{})
-				if !ok {
-					log.Fatalf(
-						"unexpected type for track: %T",
-						itemMap["track"],
-					)
-				}
-
-				uri := trackMap["uri"].(string)
-				existingTracks[uri] = true
+			if err := syncPlaylists(ctx, client, strategy); err != nil {
+				return err
 			}
+		}
 
-			newUris := []string{}
-			for _, uri := range uris {
-				if !existingTracks[uri] {
-					newUris = append(newUris, uri)
-				}
-			}
+		if *exportDir != "" {
+			return exportPartitionedPlaylists(ctx, client, strategy, *exportDir, ExportFormat(*exportFormat))
+		}
+		return nil
+	}
 
-			if len(newUris) > 0 {
-				if err := AddItemsToPlaylist(client, targetPlaylistId, newUris); err != nil {
-					log.Fatalln(err)
-				}
-				log.Printf("added %d tracks to %s", len(newUris), month)
-			}
+	if *schedule == "" {
+		if err := sync(); err != nil {
+			log.Fatalln(err)
 		}
+		return
+	}
+
+	if err := RunScheduled(ctx, *schedule, sync); err != nil {
+		log.Fatalln(err)
 	}
 }
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/zmb3/spotify/v2"
+)
+
+// PartitionStrategy assigns saved tracks to named playlist buckets.
+type PartitionStrategy interface {
+	// Bucket returns the name of the playlist track belongs in.
+	Bucket(track spotify.SavedTrack) string
+	// Describe returns a short human-readable name for the strategy, the
+	// same as its --partition flag value.
+	Describe() string
+	// Pattern returns a regex matching playlist names this strategy
+	// produces, so DeletePlaylistsByNameFormat can find and clean them up
+	// without the caller supplying its own regex.
+	Pattern() *regexp.Regexp
+}
+
+// Primer is implemented by strategies that need to fetch data up front
+// before Bucket can be called, such as genreStrategy's batched artist
+// lookup.
+type Primer interface {
+	Prime(ctx context.Context, client *spotify.Client, tracks []spotify.SavedTrack) error
+}
+
+// partitionStrategyByName returns the PartitionStrategy selected by the
+// --partition flag.
+func partitionStrategyByName(name string) (PartitionStrategy, error) {
+	switch name {
+	case "month":
+		return monthStrategy{}, nil
+	case "week":
+		return weekStrategy{}, nil
+	case "quarter":
+		return quarterStrategy{}, nil
+	case "year":
+		return yearStrategy{}, nil
+	case "decade":
+		return decadeStrategy{}, nil
+	case "artist":
+		return artistStrategy{}, nil
+	case "genre":
+		return newGenreStrategy(), nil
+	default:
+		return nil, fmt.Errorf("unknown partition strategy: %s", name)
+	}
+}
+
+// bucketFromAddedAt parses a SavedTrack's AddedAt timestamp, used by every
+// time-based strategy.
+func bucketFromAddedAt(track spotify.SavedTrack) (time.Time, error) {
+	return time.Parse(time.RFC3339, track.AddedAt)
+}
+
+// monthStrategy is the original behavior: one playlist per calendar month.
+type monthStrategy struct{}
+
+func (monthStrategy) Bucket(track spotify.SavedTrack) string {
+	t, err := bucketFromAddedAt(track)
+	if err != nil {
+		return "Unknown"
+	}
+
+	return t.Format("January '06")
+}
+
+func (monthStrategy) Describe() string { return "month" }
+
+func (monthStrategy) Pattern() *regexp.Regexp {
+	return regexp.MustCompile(`^[A-Za-z]+\s'\d{2}$`)
+}
+
+// weekStrategy buckets by ISO year and week number, e.g. "2024-W03".
+type weekStrategy struct{}
+
+func (weekStrategy) Bucket(track spotify.SavedTrack) string {
+	t, err := bucketFromAddedAt(track)
+	if err != nil {
+		return "Unknown"
+	}
+
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+func (weekStrategy) Describe() string { return "week" }
+
+func (weekStrategy) Pattern() *regexp.Regexp {
+	return regexp.MustCompile(`^\d{4}-W\d{2}$`)
+}
+
+// quarterStrategy buckets by calendar quarter, e.g. "2024 Q1".
+type quarterStrategy struct{}
+
+func (quarterStrategy) Bucket(track spotify.SavedTrack) string {
+	t, err := bucketFromAddedAt(track)
+	if err != nil {
+		return "Unknown"
+	}
+
+	quarter := (int(t.Month())-1)/3 + 1
+	return fmt.Sprintf("%d Q%d", t.Year(), quarter)
+}
+
+func (quarterStrategy) Describe() string { return "quarter" }
+
+func (quarterStrategy) Pattern() *regexp.Regexp {
+	return regexp.MustCompile(`^\d{4} Q[1-4]$`)
+}
+
+// yearStrategy buckets by the calendar year a track was saved.
+type yearStrategy struct{}
+
+func (yearStrategy) Bucket(track spotify.SavedTrack) string {
+	t, err := bucketFromAddedAt(track)
+	if err != nil {
+		return "Unknown"
+	}
+
+	return strconv.Itoa(t.Year())
+}
+
+func (yearStrategy) Describe() string { return "year" }
+
+func (yearStrategy) Pattern() *regexp.Regexp {
+	return regexp.MustCompile(`^\d{4}$`)
+}
+
+// decadeStrategy buckets by the decade the track's album was released,
+// e.g. "1990s".
+type decadeStrategy struct{}
+
+func (decadeStrategy) Bucket(track spotify.SavedTrack) string {
+	releaseDate := track.Album.ReleaseDate
+	if len(releaseDate) < 4 {
+		return "Unknown"
+	}
+
+	year, err := strconv.Atoi(releaseDate[:4])
+	if err != nil {
+		return "Unknown"
+	}
+
+	return fmt.Sprintf("%ds", (year/10)*10)
+}
+
+func (decadeStrategy) Describe() string { return "decade" }
+
+func (decadeStrategy) Pattern() *regexp.Regexp {
+	return regexp.MustCompile(`^\d{4}s$`)
+}
+
+// artistStrategy buckets by a track's primary artist.
+type artistStrategy struct{}
+
+func (artistStrategy) Bucket(track spotify.SavedTrack) string {
+	if len(track.Artists) == 0 {
+		return "Unknown"
+	}
+
+	return track.Artists[0].Name
+}
+
+func (artistStrategy) Describe() string { return "artist" }
+
+func (artistStrategy) Pattern() *regexp.Regexp {
+	// Artist names aren't a fixed shape, so this matches anything; callers
+	// cleaning up artist playlists should double check before deleting.
+	return regexp.MustCompile(`^.+$`)
+}
+
+// maxArtistsPerRequest is Spotify's hard cap on the number of artist IDs
+// accepted by a single call to the get-several-artists endpoint.
+const maxArtistsPerRequest = 50
+
+// genreStrategy buckets by the first genre of a track's primary artist.
+// Genres aren't included on a saved track, so Prime batches a lookup of
+// every distinct primary artist before Bucket can be called.
+type genreStrategy struct {
+	genreByArtist map[spotify.ID]string
+}
+
+func newGenreStrategy() *genreStrategy {
+	return &genreStrategy{genreByArtist: make(map[spotify.ID]string)}
+}
+
+func (s *genreStrategy) Prime(
+	ctx context.Context,
+	client *spotify.Client,
+	tracks []spotify.SavedTrack,
+) error {
+	seen := make(map[spotify.ID]bool)
+	var artistIds []spotify.ID
+
+	for _, track := range tracks {
+		if len(track.Artists) == 0 {
+			continue
+		}
+
+		id := track.Artists[0].ID
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		artistIds = append(artistIds, id)
+	}
+
+	for start := 0; start < len(artistIds); start += maxArtistsPerRequest {
+		end := start + maxArtistsPerRequest
+		if end > len(artistIds) {
+			end = len(artistIds)
+		}
+
+		artists, err := client.GetArtists(ctx, artistIds[start:end]...)
+		if err != nil {
+			return err
+		}
+
+		for _, artist := range artists {
+			genre := "Unknown"
+			if len(artist.Genres) > 0 {
+				genre = artist.Genres[0]
+			}
+			s.genreByArtist[artist.ID] = genre
+		}
+	}
+
+	return nil
+}
+
+func (s *genreStrategy) Bucket(track spotify.SavedTrack) string {
+	if len(track.Artists) == 0 {
+		return "Unknown"
+	}
+
+	if genre, ok := s.genreByArtist[track.Artists[0].ID]; ok {
+		return genre
+	}
+
+	return "Unknown"
+}
+
+func (s *genreStrategy) Describe() string { return "genre" }
+
+func (s *genreStrategy) Pattern() *regexp.Regexp {
+	// Genre names aren't a fixed shape, so this matches anything; callers
+	// cleaning up genre playlists should double check before deleting.
+	return regexp.MustCompile(`^.+$`)
+}
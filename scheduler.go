@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// RunScheduled parses schedule as a standard 5-field cron expression and
+// invokes runFn on that schedule until the process receives SIGINT/SIGTERM.
+// An initial sync runs a couple of seconds after startup so changes aren't
+// stuck waiting for the first tick. A mutex guards against overlapping runs
+// if a sync takes longer than the interval between ticks.
+func RunScheduled(ctx context.Context, schedule string, runFn func() error) error {
+	c := cron.New()
+
+	var mu sync.Mutex
+	runSync := func() {
+		if !mu.TryLock() {
+			log.Println("skipping run: previous sync is still in progress")
+			return
+		}
+		defer mu.Unlock()
+
+		log.Println("running sync")
+		if err := runFn(); err != nil {
+			log.Printf("sync failed: %v", err)
+			return
+		}
+		log.Println("sync complete")
+	}
+
+	if _, err := c.AddFunc(schedule, runSync); err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+
+	c.Start()
+	defer c.Stop()
+
+	go func() {
+		time.Sleep(2 * time.Second)
+		runSync()
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case sig := <-stop:
+		log.Printf("received %s, shutting down", sig)
+	case <-ctx.Done():
+	}
+
+	return nil
+}